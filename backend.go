@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// Backend creates a Session for each accepted connection. Implementations
+// decide what ultimately happens to an accepted message: write it to disk,
+// forward it somewhere, or simply discard it.
+type Backend interface {
+	NewSession(client string, remoteAddr string) Session
+}
+
+// Session receives the events of a single SMTP transaction, in order: Mail,
+// one or more Rcpt, then Data. Reset is called on RSET and before starting a
+// new transaction on the same connection. Logout is called once per Session:
+// when a new EHLO/HELO or a successful STARTTLS replaces it, or when the
+// connection is closed.
+type Session interface {
+	Mail(from string) error
+	Rcpt(to string) error
+	Data(r io.Reader) error
+	Reset()
+	Logout() error
+}
+
+// discardBackend is the default backend: it prints the accepted message to
+// stdout and keeps nothing, matching the sink's original behaviour.
+type discardBackend struct{}
+
+func (discardBackend) NewSession(client string, remoteAddr string) Session {
+	return &discardSession{}
+}
+
+type discardSession struct{}
+
+func (s *discardSession) Mail(from string) error { return nil }
+
+func (s *discardSession) Rcpt(to string) error { return nil }
+
+func (s *discardSession) Data(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== BODY BEGIN ==\n%s=== BODY END ===\n", string(data))
+
+	return nil
+}
+
+func (s *discardSession) Reset() {}
+
+func (s *discardSession) Logout() error { return nil }
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/textproto"
+	"os"
+	"sync"
+	"time"
+)
+
+// jsonlBackend appends one JSON object per accepted message to a file, one
+// per line, so the result can be tailed or processed with line-oriented
+// tools (jq, etc).
+type jsonlBackend struct {
+	path string
+
+	mu sync.Mutex
+}
+
+func newJSONLBackend(path string) *jsonlBackend {
+	return &jsonlBackend{path: path}
+}
+
+func (b *jsonlBackend) NewSession(client string, remoteAddr string) Session {
+	return &jsonlSession{backend: b}
+}
+
+type jsonlMessage struct {
+	From       string              `json:"from"`
+	To         []string            `json:"to"`
+	Headers    map[string][]string `json:"headers"`
+	Body       string              `json:"body"`
+	ReceivedAt time.Time           `json:"received_at"`
+}
+
+type jsonlSession struct {
+	backend  *jsonlBackend
+	mailFrom string
+	rcptTo   []string
+}
+
+func (s *jsonlSession) Mail(from string) error {
+	s.mailFrom = from
+	return nil
+}
+
+func (s *jsonlSession) Rcpt(to string) error {
+	s.rcptTo = append(s.rcptTo, to)
+	return nil
+}
+
+func (s *jsonlSession) Data(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	headers, body := splitHeadersAndBody(data)
+
+	msg := jsonlMessage{
+		From:       s.mailFrom,
+		To:         s.rcptTo,
+		Headers:    headers,
+		Body:       string(body),
+		ReceivedAt: time.Now().UTC(),
+	}
+
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("jsonl: marshal: %w", err)
+	}
+
+	s.backend.mu.Lock()
+	defer s.backend.mu.Unlock()
+
+	f, err := os.OpenFile(s.backend.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("jsonl: open: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("jsonl: write: %w", err)
+	}
+
+	slog.Info("Appended message to jsonl sink", "path", s.backend.path)
+
+	return nil
+}
+
+func (s *jsonlSession) Reset() {
+	s.mailFrom = ""
+	s.rcptTo = nil
+}
+
+func (s *jsonlSession) Logout() error { return nil }
+
+// splitHeadersAndBody parses the RFC5322 headers off the front of data and
+// returns them alongside the remaining body. If not even the first header
+// line parses, no headers are returned and the whole message is treated as
+// body; if parsing fails partway, the headers read so far are returned and
+// the body is what follows the malformed line.
+func splitHeadersAndBody(data []byte) (map[string][]string, []byte) {
+	r := textproto.NewReader(bufio.NewReader(bytes.NewReader(data)))
+
+	hdr, err := r.ReadMIMEHeader()
+	if err != nil && len(hdr) == 0 {
+		return map[string][]string{}, data
+	}
+
+	rest, _ := io.ReadAll(r.R)
+
+	return map[string][]string(hdr), rest
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// maildirBackend writes each accepted message as a new file in a Maildir
+// (https://cr.yp.to/proto/maildir.html) rooted at dir. Messages are written
+// to tmp/ and atomically renamed into new/ once fully flushed to disk, so a
+// reader watching new/ never observes a partial file.
+type maildirBackend struct {
+	dir string
+
+	counter atomic.Uint64
+}
+
+// newMaildirBackend creates the tmp/, new/ and cur/ subdirectories of dir if
+// they don't already exist.
+func newMaildirBackend(dir string) (*maildirBackend, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("maildir: %w", err)
+		}
+	}
+
+	return &maildirBackend{dir: dir}, nil
+}
+
+func (b *maildirBackend) NewSession(client string, remoteAddr string) Session {
+	return &maildirSession{backend: b}
+}
+
+type maildirSession struct {
+	backend *maildirBackend
+}
+
+func (s *maildirSession) Mail(from string) error { return nil }
+
+func (s *maildirSession) Rcpt(to string) error { return nil }
+
+func (s *maildirSession) Data(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	name := s.backend.filename()
+
+	tmpPath := filepath.Join(s.backend.dir, "tmp", name)
+	newPath := filepath.Join(s.backend.dir, "new", name)
+
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("maildir: create tmp file: %w", err)
+	}
+
+	if _, err := io.Copy(f, bytes.NewReader(data)); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("maildir: write tmp file: %w", err)
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("maildir: sync tmp file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("maildir: close tmp file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, newPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("maildir: deliver to new: %w", err)
+	}
+
+	slog.Info("Delivered message to maildir", "path", newPath)
+
+	return nil
+}
+
+func (s *maildirSession) Reset() {}
+
+func (s *maildirSession) Logout() error { return nil }
+
+// filename builds a Maildir-unique-name: "time.pid_counter.host".
+func (b *maildirBackend) filename() string {
+	return fmt.Sprintf(
+		"%d.%d_%d.%s",
+		time.Now().UnixNano(),
+		os.Getpid(),
+		b.counter.Add(1),
+		hostnameOrUnknown(),
+	)
+}
+
+func hostnameOrUnknown() string {
+	h, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+
+	return h
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mboxBackend appends each accepted message to a single mbox file, in the
+// classic "From " separated format. Writes are serialized with a mutex
+// because the mbox format has no way to interleave concurrent appends
+// safely.
+type mboxBackend struct {
+	path string
+
+	mu sync.Mutex
+}
+
+func newMboxBackend(path string) *mboxBackend {
+	return &mboxBackend{path: path}
+}
+
+func (b *mboxBackend) NewSession(client string, remoteAddr string) Session {
+	return &mboxSession{backend: b}
+}
+
+type mboxSession struct {
+	backend  *mboxBackend
+	mailFrom string
+}
+
+func (s *mboxSession) Mail(from string) error {
+	s.mailFrom = from
+	return nil
+}
+
+func (s *mboxSession) Rcpt(to string) error { return nil }
+
+func (s *mboxSession) Data(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.backend.mu.Lock()
+	defer s.backend.mu.Unlock()
+
+	f, err := os.OpenFile(s.backend.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("mbox: open: %w", err)
+	}
+	defer f.Close()
+
+	bw := bufio.NewWriter(f)
+
+	fmt.Fprintf(
+		bw,
+		"From %s %s\n",
+		mboxFromAddr(s.mailFrom),
+		time.Now().UTC().Format("Mon Jan _2 15:04:05 2006"),
+	)
+	writeMboxEscaped(bw, data)
+	bw.WriteString("\n")
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("mbox: write: %w", err)
+	}
+
+	slog.Info("Appended message to mbox", "path", s.backend.path)
+
+	return nil
+}
+
+func (s *mboxSession) Reset() {
+	s.mailFrom = ""
+}
+
+func (s *mboxSession) Logout() error { return nil }
+
+// mboxFromAddr returns the envelope sender for use in a "From " line,
+// falling back to "MAILER-DAEMON" when the envelope sender is empty (as for
+// a bounce).
+func mboxFromAddr(mailFrom string) string {
+	addr := strings.Trim(mailFrom, "<>")
+	if addr == "" {
+		return "MAILER-DAEMON"
+	}
+
+	return addr
+}
+
+// writeMboxEscaped writes data to w using the "mboxrd" convention: any line
+// matching "^>*From " is given one extra leading ">" so it can never be
+// mistaken for a new message boundary when the mbox is re-read.
+func writeMboxEscaped(w *bufio.Writer, data []byte) {
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		trimmed := bytes.TrimRight(line, "\r")
+
+		rest := bytes.TrimLeft(trimmed, ">")
+		if bytes.HasPrefix(rest, []byte("From ")) {
+			w.WriteString(">")
+		}
+
+		w.Write(line)
+
+		if i != len(lines)-1 {
+			w.WriteString("\n")
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// webhookBackend POSTs the raw RFC5322 message to url for every accepted
+// message, retrying with exponential backoff on failure.
+type webhookBackend struct {
+	url       string
+	client    *http.Client
+	retries   int
+	baseDelay time.Duration
+}
+
+func newWebhookBackend(url string, retries int, baseDelay time.Duration) *webhookBackend {
+	return &webhookBackend{
+		url:       url,
+		client:    &http.Client{Timeout: 30 * time.Second},
+		retries:   retries,
+		baseDelay: baseDelay,
+	}
+}
+
+func (b *webhookBackend) NewSession(client string, remoteAddr string) Session {
+	return &webhookSession{backend: b}
+}
+
+type webhookSession struct {
+	backend *webhookBackend
+}
+
+func (s *webhookSession) Mail(from string) error { return nil }
+
+func (s *webhookSession) Rcpt(to string) error { return nil }
+
+func (s *webhookSession) Data(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return s.backend.deliver(data)
+}
+
+func (s *webhookSession) Reset() {}
+
+func (s *webhookSession) Logout() error { return nil }
+
+// deliver POSTs data to the webhook URL, retrying up to b.retries times with
+// exponential backoff starting at b.baseDelay.
+func (b *webhookBackend) deliver(data []byte) error {
+	var lastErr error
+
+	for attempt := 0; attempt <= b.retries; attempt++ {
+		if attempt > 0 {
+			delay := b.baseDelay * time.Duration(1<<(attempt-1))
+			slog.Info("Retrying webhook delivery", "attempt", attempt, "delay", delay)
+			time.Sleep(delay)
+		}
+
+		req, err := http.NewRequest(http.MethodPost, b.url, bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("webhook: build request: %w", err)
+		}
+		req.Header.Set("Content-Type", "message/rfc822")
+
+		resp, err := b.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			slog.Info("Delivered message via webhook", "url", b.url, "status", resp.StatusCode)
+			return nil
+		}
+
+		lastErr = fmt.Errorf("webhook: unexpected status %d", resp.StatusCode)
+	}
+
+	return fmt.Errorf("webhook: giving up after %d attempts: %w", b.retries+1, lastErr)
+}
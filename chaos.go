@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// chaosRule describes one scripted failure: which verb (or any verb, when
+// Verb is empty) it applies to, when it fires, and what to do instead of
+// running the command normally.
+type chaosRule struct {
+	Verb        string        `yaml:"verb"`
+	After       int           `yaml:"after"`       // fire once the Nth matching command is seen
+	Every       int           `yaml:"every"`       // fire on every Kth matching command
+	Probability float64       `yaml:"probability"` // fire with this probability, 0..1
+	Code        int           `yaml:"code"`
+	Message     string        `yaml:"message"`
+	Stall       time.Duration `yaml:"stall"`
+	Truncate    bool          `yaml:"truncate"`
+	Malformed   string        `yaml:"malformed"` // "missing-crlf" or "bad-code-width"
+}
+
+// injector applies chaosRules to the command loop. It's safe for
+// concurrent use by multiple sessions; per-verb counts are shared across
+// all connections so e.g. "every Kth RCPT" means the Kth RCPT the server
+// has seen overall.
+type injector struct {
+	rules []chaosRule
+	rng   *rand.Rand
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newInjector(rules []chaosRule, seed int64) *injector {
+	return &injector{
+		rules:  rules,
+		rng:    rand.New(rand.NewSource(seed)),
+		counts: map[string]int{},
+	}
+}
+
+// Before is consulted by the command dispatch before running verb
+// normally. It returns the rule to apply and true if one matched.
+func (in *injector) Before(verb string, sess *session) (chaosRule, bool) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	in.counts[verb]++
+
+	total := 0
+	for _, n := range in.counts {
+		total += n
+	}
+
+	for _, rule := range in.rules {
+		if rule.Verb != "" && rule.Verb != verb {
+			continue
+		}
+
+		// A rule without a verb matches every command, so it counts
+		// commands of all verbs together.
+		count := in.counts[verb]
+		if rule.Verb == "" {
+			count = total
+		}
+
+		matched := false
+		switch {
+		case rule.After > 0:
+			matched = count == rule.After
+		case rule.Every > 0:
+			matched = count%rule.Every == 0
+		case rule.Probability > 0:
+			matched = in.rng.Float64() < rule.Probability
+		}
+
+		if matched {
+			slog.Info(
+				"Chaos injection triggered",
+				"verb", verb, "count", count,
+				"code", rule.Code, "truncate", rule.Truncate, "malformed", rule.Malformed,
+			)
+
+			return rule, true
+		}
+	}
+
+	return chaosRule{}, false
+}
+
+// isStallOnly reports whether rule's only effect is delaying the command,
+// with no action to substitute for the real handler.
+func (rule chaosRule) isStallOnly() bool {
+	return rule.Stall > 0 && rule.Code == 0 && rule.Message == "" && !rule.Truncate && rule.Malformed == ""
+}
+
+// apply carries out rule's action in place of the normal command handling.
+// It returns true if the connection has been dropped, in which case the
+// caller must not write anything more to it (not even the usual closing
+// reply). Callers should check isStallOnly first: apply always substitutes
+// a reply (or closes the connection) for the real handler, so a stall-only
+// rule has nothing to apply here and should instead delay and let normal
+// dispatch proceed.
+func (rule chaosRule) apply(conn net.Conn, bw *bufio.Writer) (quit bool) {
+	if rule.Stall > 0 {
+		time.Sleep(rule.Stall)
+	}
+
+	if rule.Truncate {
+		// Simulate a dropped connection: close it directly rather than
+		// letting the caller send its usual orderly closing reply.
+		conn.Close()
+		return true
+	}
+
+	switch rule.Malformed {
+	case "missing-crlf":
+		fmt.Fprintf(bw, "%d %s\n", rule.Code, rule.Message)
+		bw.Flush()
+		return false
+	case "bad-code-width":
+		fmt.Fprintf(bw, "%d %s\r\n", rule.Code%100, rule.Message)
+		bw.Flush()
+		return false
+	}
+
+	writeReplyAndFlush(bw, rule.Code, rule.Message)
+
+	return false
+}
+
+// loadChaosRules merges rules parsed from a YAML file (may be empty) with
+// rules given as repeated --chaos key=value,... flags.
+func loadChaosRules(file string, specs []string) ([]chaosRule, error) {
+	var rules []chaosRule
+
+	if file != "" {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("chaos: read %s: %w", file, err)
+		}
+
+		if err := yaml.Unmarshal(raw, &rules); err != nil {
+			return nil, fmt.Errorf("chaos: parse %s: %w", file, err)
+		}
+	}
+
+	for _, spec := range specs {
+		rule, err := parseChaosSpec(spec)
+		if err != nil {
+			return nil, fmt.Errorf("chaos: %q: %w", spec, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// parseChaosSpec parses a single --chaos flag value, a comma-separated list
+// of key=value pairs, e.g. "verb=RCPT,every=3,code=451,message=Try again".
+func parseChaosSpec(spec string) (chaosRule, error) {
+	var rule chaosRule
+
+	for _, field := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return rule, fmt.Errorf("malformed field %q", field)
+		}
+
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch key {
+		case "verb":
+			rule.Verb = strings.ToUpper(value)
+		case "after":
+			rule.After, err = strconv.Atoi(value)
+		case "every":
+			rule.Every, err = strconv.Atoi(value)
+		case "probability":
+			rule.Probability, err = strconv.ParseFloat(value, 64)
+		case "code":
+			rule.Code, err = strconv.Atoi(value)
+		case "message":
+			rule.Message = value
+		case "stall":
+			rule.Stall, err = time.ParseDuration(value)
+		case "truncate":
+			rule.Truncate, err = strconv.ParseBool(value)
+		case "malformed":
+			rule.Malformed = value
+		default:
+			return rule, fmt.Errorf("unknown field %q", key)
+		}
+
+		if err != nil {
+			return rule, fmt.Errorf("field %q: %w", key, err)
+		}
+	}
+
+	return rule, nil
+}
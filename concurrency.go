@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultMaxMessageSize is used when --max-message-size isn't set,
+// preserving the sink's original DATA size limit.
+const defaultMaxMessageSize = 1024 * 1024 * 30
+
+// connLimiter bounds how many connections may be served concurrently,
+// globally and per source IP.
+type connLimiter struct {
+	maxTotal int
+	maxPerIP int
+
+	mu    sync.Mutex
+	total int
+	perIP map[string]int
+}
+
+func newConnLimiter(maxTotal, maxPerIP int) *connLimiter {
+	return &connLimiter{
+		maxTotal: maxTotal,
+		maxPerIP: maxPerIP,
+		perIP:    map[string]int{},
+	}
+}
+
+// tryAcquire reserves a slot for ip, returning false if doing so would
+// exceed the global or per-IP cap (a limit of 0 means unlimited).
+func (l *connLimiter) tryAcquire(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxTotal > 0 && l.total >= l.maxTotal {
+		return false
+	}
+
+	if l.maxPerIP > 0 && l.perIP[ip] >= l.maxPerIP {
+		return false
+	}
+
+	l.total++
+	l.perIP[ip]++
+
+	return true
+}
+
+func (l *connLimiter) release(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.total--
+
+	l.perIP[ip]--
+	if l.perIP[ip] <= 0 {
+		delete(l.perIP, ip)
+	}
+}
+
+// Serve accepts connections from l and handles each on its own goroutine,
+// bounded by --max-connections and --max-connections-per-ip. It stops
+// accepting on SIGINT/SIGTERM, gives in-flight sessions up to
+// --shutdown-grace to finish, then returns.
+func (s *server) Serve(l net.Listener) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	limiter := newConnLimiter(s.maxConnections, s.maxConnsPerIP)
+
+	var wg sync.WaitGroup
+
+	live := map[net.Conn]struct{}{}
+	var liveMu sync.Mutex
+
+	go func() {
+		<-ctx.Done()
+		slog.Info("Shutting down, no longer accepting new connections")
+		l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("Failed to accept", "error", err.Error())
+			}
+
+			break
+		}
+
+		host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+		if err != nil {
+			host = conn.RemoteAddr().String()
+		}
+
+		if !limiter.tryAcquire(host) {
+			slog.Info("Rejecting connection: limit reached", "remote_addr", conn.RemoteAddr().String())
+			writeReplyAndFlush(bufio.NewWriter(conn), 421, "Too many connections, try again later")
+			conn.Close()
+			continue
+		}
+
+		liveMu.Lock()
+		live[conn] = struct{}{}
+		liveMu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer limiter.release(host)
+			defer conn.Close()
+			defer func() {
+				liveMu.Lock()
+				delete(live, conn)
+				liveMu.Unlock()
+			}()
+			defer func() {
+				if r := recover(); r != nil {
+					slog.Error("Session panicked", "error", r)
+				}
+			}()
+
+			s.serveConn(conn)
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(s.shutdownGrace):
+		slog.Info("Shutdown grace period elapsed, closing remaining connections")
+
+		liveMu.Lock()
+		for conn := range live {
+			conn.Close()
+		}
+		liveMu.Unlock()
+
+		<-done
+	}
+
+	return nil
+}
@@ -2,13 +2,17 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"log/slog"
 	"net"
 	"net/textproto"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -32,9 +36,12 @@ const (
 )
 
 type session struct {
-	client string
-	state  sessionState
-	tx     *transaction
+	client        string
+	state         sessionState
+	tx            *transaction
+	backend       Session
+	tls           bool
+	authenticated bool
 }
 
 type transaction struct {
@@ -44,7 +51,33 @@ type transaction struct {
 }
 
 func realmain() error {
-	var serverName string
+	var (
+		serverName    string
+		maildirPath   string
+		mboxPath      string
+		jsonlPath     string
+		webhookURL    string
+		webhookRetry  int
+		webhookBackof time.Duration
+		tlsCert       string
+		tlsKey        string
+		tlsClientAuth string
+		implicitTLS   bool
+		requireTLS    bool
+		authAcceptAny bool
+		authFile      string
+		authExec      string
+		chaosFile     string
+		chaosSpecs    []string
+		chaosSeed     int64
+		proxyProto    string
+		maxConns      int
+		maxConnsPerIP int
+		readTimeout   time.Duration
+		sessTimeout   time.Duration
+		maxMsgSize    int64
+		shutdownGrace time.Duration
+	)
 
 	rootCmd := &cobra.Command{
 		Use:   "go-smts-sink",
@@ -57,9 +90,70 @@ func realmain() error {
 
 			addr := args[0]
 
+			backend, err := selectBackend(maildirPath, mboxPath, jsonlPath, webhookURL, webhookRetry, webhookBackof)
+			if err != nil {
+				slog.Error("Failed to set up backend", "error", err.Error())
+				return
+			}
+
+			tlsConfig, err := loadTLSConfig(tlsCert, tlsKey, tlsClientAuthMode(tlsClientAuth))
+			if err != nil {
+				slog.Error("Failed to set up TLS", "error", err.Error())
+				return
+			}
+
+			if implicitTLS && tlsConfig == nil {
+				slog.Error("--implicit-tls requires --tls-cert and --tls-key")
+				return
+			}
+
+			authenticator, err := selectAuthenticator(authAcceptAny, authFile, authExec)
+			if err != nil {
+				slog.Error("Failed to set up authenticator", "error", err.Error())
+				return
+			}
+
+			chaosRules, err := loadChaosRules(chaosFile, chaosSpecs)
+			if err != nil {
+				slog.Error("Failed to set up chaos injection", "error", err.Error())
+				return
+			}
+
+			var inj *injector
+			if len(chaosRules) > 0 {
+				inj = newInjector(chaosRules, chaosSeed)
+			}
+
+			proxyMode := proxyProtocolMode(proxyProto)
+			switch proxyMode {
+			case proxyProtocolOff, proxyProtocolV1, proxyProtocolV2, proxyProtocolOptional:
+			default:
+				slog.Error("Unknown --proxy-protocol value", "value", proxyProto)
+				return
+			}
+
 			slog.Info(fmt.Sprintf("Listening to %s...", addr))
 
-			srv := &server{hostname: serverName}
+			if maxMsgSize <= 0 {
+				maxMsgSize = defaultMaxMessageSize
+			}
+
+			srv := &server{
+				hostname:       serverName,
+				backend:        backend,
+				tlsConfig:      tlsConfig,
+				implicitTLS:    implicitTLS,
+				requireTLS:     requireTLS,
+				authenticator:  authenticator,
+				injector:       inj,
+				proxyProtocol:  proxyMode,
+				maxConnections: maxConns,
+				maxConnsPerIP:  maxConnsPerIP,
+				readTimeout:    readTimeout,
+				sessionTimeout: sessTimeout,
+				maxMessageSize: maxMsgSize,
+				shutdownGrace:  shutdownGrace,
+			}
 
 			l, err := net.Listen("tcp", addr)
 			if err != nil {
@@ -69,18 +163,12 @@ func realmain() error {
 
 			defer l.Close()
 
-			for {
-				func() {
-					conn, err := l.Accept()
-					if err != nil {
-						slog.Error("Failed to accept", "error", err.Error())
-						return
-					}
-
-					defer conn.Close()
+			if implicitTLS {
+				l = tls.NewListener(l, tlsConfig)
+			}
 
-					srv.serveConn(conn)
-				}()
+			if err := srv.Serve(l); err != nil {
+				slog.Error("Serve failed", "error", err.Error())
 			}
 		},
 	}
@@ -92,23 +180,288 @@ func realmain() error {
 		"specify a server name",
 	)
 
+	rootCmd.Flags().StringVar(
+		&maildirPath,
+		"maildir",
+		"",
+		"deliver accepted messages into a Maildir rooted at this path",
+	)
+	rootCmd.Flags().StringVar(
+		&mboxPath,
+		"mbox",
+		"",
+		"append accepted messages to this mbox file",
+	)
+	rootCmd.Flags().StringVar(
+		&jsonlPath,
+		"jsonl",
+		"",
+		"append one JSON object per accepted message to this file",
+	)
+	rootCmd.Flags().StringVar(
+		&webhookURL,
+		"webhook-url",
+		"",
+		"POST each accepted message to this URL",
+	)
+	rootCmd.Flags().IntVar(
+		&webhookRetry,
+		"webhook-retries",
+		3,
+		"number of retries for a failed webhook delivery",
+	)
+	rootCmd.Flags().DurationVar(
+		&webhookBackof,
+		"webhook-backoff",
+		time.Second,
+		"base delay between webhook delivery retries, doubled on every attempt",
+	)
+
+	rootCmd.Flags().StringVar(
+		&tlsCert,
+		"tls-cert",
+		"",
+		"path to a PEM certificate used for STARTTLS/implicit TLS",
+	)
+	rootCmd.Flags().StringVar(
+		&tlsKey,
+		"tls-key",
+		"",
+		"path to the PEM private key matching --tls-cert",
+	)
+	rootCmd.Flags().StringVar(
+		&tlsClientAuth,
+		"tls-client-auth",
+		"none",
+		"client certificate requirement: none, request, or require",
+	)
+	rootCmd.Flags().BoolVar(
+		&implicitTLS,
+		"implicit-tls",
+		false,
+		"serve SMTPS (TLS from the first byte) instead of advertising STARTTLS",
+	)
+	rootCmd.Flags().BoolVar(
+		&requireTLS,
+		"require-tls",
+		false,
+		"reject MAIL commands received before TLS has been negotiated",
+	)
+
+	rootCmd.Flags().BoolVar(
+		&authAcceptAny,
+		"auth-accept-any",
+		false,
+		"advertise AUTH and accept any username/password",
+	)
+	rootCmd.Flags().StringVar(
+		&authFile,
+		"auth-file",
+		"",
+		"path to a YAML or JSON file of username: password pairs",
+	)
+	rootCmd.Flags().StringVar(
+		&authExec,
+		"auth-exec",
+		"",
+		"path to a subprocess that verifies credentials (username/password on stdin, exit code is the verdict)",
+	)
+
+	rootCmd.Flags().StringVar(
+		&chaosFile,
+		"chaos-file",
+		"",
+		"path to a YAML file of chaos rules for failure-injection testing",
+	)
+	rootCmd.Flags().StringArrayVar(
+		&chaosSpecs,
+		"chaos",
+		nil,
+		"inline chaos rule, e.g. \"verb=RCPT,every=3,code=451,message=Try again\" (repeatable)",
+	)
+	rootCmd.Flags().Int64Var(
+		&chaosSeed,
+		"chaos-seed",
+		1,
+		"seed for the chaos PRNG, for reproducible probability-based rules",
+	)
+
+	rootCmd.Flags().StringVar(
+		&proxyProto,
+		"proxy-protocol",
+		"off",
+		"expect a PROXY protocol header ahead of the SMTP session: off, v1, v2, or optional",
+	)
+
+	rootCmd.Flags().IntVar(
+		&maxConns,
+		"max-connections",
+		0,
+		"maximum number of concurrent connections (0 = unlimited)",
+	)
+	rootCmd.Flags().IntVar(
+		&maxConnsPerIP,
+		"max-connections-per-ip",
+		0,
+		"maximum number of concurrent connections from a single IP (0 = unlimited)",
+	)
+	rootCmd.Flags().DurationVar(
+		&readTimeout,
+		"read-timeout",
+		0,
+		"idle timeout for reading a single command (0 = no timeout)",
+	)
+	rootCmd.Flags().DurationVar(
+		&sessTimeout,
+		"session-timeout",
+		0,
+		"maximum total duration of a single connection (0 = no timeout)",
+	)
+	rootCmd.Flags().Int64Var(
+		&maxMsgSize,
+		"max-message-size",
+		defaultMaxMessageSize,
+		"maximum accepted DATA size in bytes, advertised as SIZE in EHLO",
+	)
+	rootCmd.Flags().DurationVar(
+		&shutdownGrace,
+		"shutdown-grace",
+		10*time.Second,
+		"time to let in-flight sessions finish after SIGINT/SIGTERM before dropping them",
+	)
+
 	return rootCmd.Execute()
 }
 
+// selectBackend picks the sink's delivery backend from the configured
+// flags. At most one of maildir/mbox/jsonl/webhook may be set; when none
+// are, the sink falls back to its original stdout-only behaviour.
+func selectBackend(
+	maildirPath, mboxPath, jsonlPath, webhookURL string,
+	webhookRetries int,
+	webhookBackoff time.Duration,
+) (Backend, error) {
+	set := 0
+	for _, v := range []string{maildirPath, mboxPath, jsonlPath, webhookURL} {
+		if v != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return nil, fmt.Errorf("only one of --maildir, --mbox, --jsonl, --webhook-url may be set")
+	}
+
+	switch {
+	case maildirPath != "":
+		return newMaildirBackend(maildirPath)
+	case mboxPath != "":
+		return newMboxBackend(mboxPath), nil
+	case jsonlPath != "":
+		return newJSONLBackend(jsonlPath), nil
+	case webhookURL != "":
+		return newWebhookBackend(webhookURL, webhookRetries, webhookBackoff), nil
+	default:
+		return discardBackend{}, nil
+	}
+}
+
+// selectAuthenticator picks the sink's AUTH backend from the configured
+// flags. At most one of auth-accept-any/auth-file/auth-exec may be set;
+// when none are, AUTH is not advertised at all.
+func selectAuthenticator(acceptAny bool, authFile, authExec string) (Authenticator, error) {
+	set := 0
+	if acceptAny {
+		set++
+	}
+	for _, v := range []string{authFile, authExec} {
+		if v != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return nil, fmt.Errorf("only one of --auth-accept-any, --auth-file, --auth-exec may be set")
+	}
+
+	switch {
+	case acceptAny:
+		return acceptAnyAuthenticator{}, nil
+	case authFile != "":
+		return loadStaticAuthenticator(authFile)
+	case authExec != "":
+		return newExecAuthenticator(authExec), nil
+	default:
+		return nil, nil
+	}
+}
+
 type server struct {
-	hostname string
+	hostname      string
+	backend       Backend
+	tlsConfig     *tls.Config
+	implicitTLS   bool
+	requireTLS    bool
+	authenticator Authenticator
+	injector      *injector
+	proxyProtocol proxyProtocolMode
+
+	maxConnections int
+	maxConnsPerIP  int
+	readTimeout    time.Duration
+	sessionTimeout time.Duration
+	maxMessageSize int64
+	shutdownGrace  time.Duration
 }
 
 func (s *server) serveConn(conn net.Conn) {
 	br := bufio.NewReader(conn)
 	bw := bufio.NewWriter(conn)
 
+	remoteAddr := conn.RemoteAddr().String()
+
+	if s.proxyProtocol != proxyProtocolOff {
+		hdr, ok, err := readProxyHeader(conn, br, s.proxyProtocol)
+		if err != nil {
+			slog.Error("Failed to read PROXY protocol header", "error", err.Error())
+			return
+		}
+
+		if ok {
+			if hdr.sourceAddr != "" {
+				remoteAddr = hdr.sourceAddr
+			}
+
+			slog.Info("Decoded PROXY protocol header", hdr.logFields()...)
+		}
+	}
+
+	var sessionDeadline time.Time
+	if s.sessionTimeout > 0 {
+		sessionDeadline = time.Now().Add(s.sessionTimeout)
+		conn.SetDeadline(sessionDeadline)
+	}
+
 	writeReplyAndFlush(bw, 220, fmt.Sprintf("%s ESMTP", s.hostname))
 
-	sess := &session{}
+	sess := &session{tls: s.implicitTLS}
 
-	var quit bool
+	defer func() {
+		if sess.backend != nil {
+			if err := sess.backend.Logout(); err != nil {
+				slog.Error("Backend logout failed", "error", err.Error())
+			}
+		}
+	}()
+
+	var quit, dropped bool
 	for !quit {
+		if s.readTimeout > 0 {
+			deadline := time.Now().Add(s.readTimeout)
+			if !sessionDeadline.IsZero() && sessionDeadline.Before(deadline) {
+				deadline = sessionDeadline
+			}
+			conn.SetReadDeadline(deadline)
+		}
+
 		verb, args, err := readCommand(br)
 
 		if err != nil {
@@ -130,10 +483,31 @@ func (s *server) serveConn(conn net.Conn) {
 		// 	QUIT
 		// 	VRFY
 
+		if s.injector != nil {
+			if rule, matched := s.injector.Before(verb, sess); matched {
+				if rule.isStallOnly() {
+					time.Sleep(rule.Stall)
+				} else {
+					if rule.apply(conn, bw) {
+						quit = true
+						dropped = true
+					}
+					continue
+				}
+			}
+		}
+
 		switch verb {
 		case "EHLO", "HELO":
-			// reset to an initial state
-			sess = &session{}
+			if sess.backend != nil {
+				if err := sess.backend.Logout(); err != nil {
+					slog.Error("Backend logout failed", "error", err.Error())
+				}
+			}
+
+			// reset to an initial state, keeping whether TLS has already
+			// been negotiated on this connection
+			sess = &session{tls: sess.tls}
 
 			if args == "" {
 				args = "unknown"
@@ -141,18 +515,96 @@ func (s *server) serveConn(conn net.Conn) {
 
 			sess.client = args
 			sess.state = beforeMAIL
-			writeReplyAndFlush(
-				bw,
-				250,
-				fmt.Sprintf("%s greets %s", s.hostname, sess.client),
+			sess.backend = s.backend.NewSession(sess.client, remoteAddr)
+
+			greeting := fmt.Sprintf("%s greets %s", s.hostname, sess.client)
+			lines := []string{greeting}
+
+			if verb == "EHLO" {
+				lines = append(lines, fmt.Sprintf("SIZE %d", s.maxMessageSize))
+				lines = append(lines, "PIPELINING", "CHUNKING", "BINARYMIME")
+
+				if s.tlsConfig != nil && !sess.tls {
+					lines = append(lines, "STARTTLS")
+				}
+
+				if s.authenticator != nil && (s.tlsConfig == nil || sess.tls) {
+					mechanisms := "AUTH PLAIN LOGIN"
+					if _, ok := s.authenticator.(SecretAuthenticator); ok {
+						mechanisms += " CRAM-MD5"
+					}
+					lines = append(lines, mechanisms)
+				}
+			}
+
+			writeReplyAndFlush(bw, 250, lines...)
+
+		case "STARTTLS":
+			if s.tlsConfig == nil {
+				writeReplyAndFlush(bw, 502, "Command not implemented")
+				continue
+			}
+
+			if sess.tls {
+				respBadSequenceOfCommands(bw)
+				continue
+			}
+
+			if args != "" {
+				respInvalidSyntax(bw)
+				continue
+			}
+
+			writeReplyAndFlush(bw, 220, "Ready to start TLS")
+
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				slog.Error("TLS handshake failed", "error", err.Error())
+				quit = true
+				continue
+			}
+
+			conn = tlsConn
+			br = bufio.NewReader(conn)
+			bw = bufio.NewWriter(conn)
+
+			cs := tlsConn.ConnectionState()
+
+			var clientCN string
+			if len(cs.PeerCertificates) > 0 {
+				clientCN = cs.PeerCertificates[0].Subject.CommonName
+			}
+
+			slog.Info(
+				"TLS negotiated",
+				"cipher_suite", tls.CipherSuiteName(cs.CipherSuite),
+				"client_cn", clientCN,
 			)
 
+			// RFC 3207: discard any prior EHLO/HELO state and require the
+			// client to issue a fresh one.
+			if sess.backend != nil {
+				if err := sess.backend.Logout(); err != nil {
+					slog.Error("Backend logout failed", "error", err.Error())
+				}
+			}
+
+			sess = &session{tls: true}
+
+		case "AUTH":
+			s.handleAUTH(sess, br, bw, args)
+
 		case "MAIL":
 			if sess.state != beforeMAIL {
 				respBadSequenceOfCommands(bw)
 				continue
 			}
 
+			if s.requireTLS && !sess.tls {
+				writeReplyAndFlush(bw, 530, "Must issue a STARTTLS command first")
+				continue
+			}
+
 			sess.tx = &transaction{}
 
 			// TODO: handle Mail-parameters
@@ -162,7 +614,13 @@ func (s *server) serveConn(conn net.Conn) {
 				continue
 			}
 
-			slog.Info("Received MAIL FROM", "mail_from", mailFrom)
+			slog.Info("Received MAIL FROM", "mail_from", mailFrom, "remote_addr", remoteAddr)
+
+			if err := sess.backend.Mail(mailFrom); err != nil {
+				slog.Error("Backend rejected MAIL FROM", "error", err.Error())
+				writeReplyAndFlush(bw, 451, "Requested action aborted: error in processing")
+				continue
+			}
 
 			sess.tx.mailFrom = mailFrom
 			sess.state = beforeRCPT
@@ -184,7 +642,13 @@ func (s *server) serveConn(conn net.Conn) {
 
 			// TODO: check the total number of recipients
 
-			slog.Info("Received RCPT TO", "rcpt_to", rcptTo)
+			slog.Info("Received RCPT TO", "rcpt_to", rcptTo, "remote_addr", remoteAddr)
+
+			if err := sess.backend.Rcpt(rcptTo); err != nil {
+				slog.Error("Backend rejected RCPT TO", "error", err.Error())
+				writeReplyAndFlush(bw, 451, "Requested action aborted: error in processing")
+				continue
+			}
 
 			sess.tx.rcptTo = append(sess.tx.rcptTo, rcptTo)
 			sess.state = beforeDATA
@@ -200,25 +664,107 @@ func (s *server) serveConn(conn net.Conn) {
 			writeReplyAndFlush(bw, 354, "Start mail input; end with <CRLF>.<CRLF>")
 			sess.state = inDATA
 
-			// limit to 30MB
-			lr := io.LimitReader(br, 1024*1024*30)
-			tr := textproto.NewReader(bufio.NewReader(lr))
+			// Read directly off br's own buffer rather than wrapping it in
+			// a fresh bufio.Reader: textproto.NewReader would otherwise
+			// read ahead into a buffer that's discarded once this case
+			// returns, silently dropping any commands the client
+			// pipelined after the DATA terminator.
+			tr := &textproto.Reader{R: br}
 			dr := tr.DotReader()
 
-			data, err := io.ReadAll(dr)
+			data, err := io.ReadAll(io.LimitReader(dr, s.maxMessageSize+1))
 			if err != nil {
 				slog.Error("Failed to read DATA", "error", err.Error())
 			}
 
+			if int64(len(data)) > s.maxMessageSize {
+				// The body exceeded the limit, so dr hasn't reached the
+				// terminating "."; drain the rest of it so the next
+				// readCommand doesn't parse leftover message lines as
+				// SMTP commands.
+				if _, derr := io.Copy(io.Discard, dr); derr != nil {
+					slog.Error("Failed to drain oversize DATA", "error", derr.Error())
+				}
+
+				writeReplyAndFlush(bw, 552, "Message size exceeds fixed maximum message size")
+				sess.state = afterDATA
+				continue
+			}
+
 			sess.tx.data = data
 
-			fmt.Printf("=== BODY BEGIN ==\n%s=== BODY END ===\n", string(data))
+			if err := sess.backend.Data(bytes.NewReader(data)); err != nil {
+				slog.Error("Backend failed to accept message", "error", err.Error())
+				writeReplyAndFlush(bw, 554, "Transaction failed")
+				sess.state = afterDATA
+				continue
+			}
 
-			// TODO: processing
 			respOK(bw)
 
 			sess.state = afterDATA
 
+		case "BDAT":
+			if sess.state != beforeDATA && sess.state != inDATA {
+				respBadSequenceOfCommands(bw)
+				continue
+			}
+
+			size, last, err := parseBDATArgs(args)
+			if err != nil {
+				respInvalidSyntax(bw)
+				continue
+			}
+
+			if sess.tx == nil {
+				sess.tx = &transaction{}
+			}
+
+			// Check the claimed chunk size against the limit before
+			// allocating or reading it: size comes straight from the
+			// client, and an unbounded make([]byte, size) lets "BDAT
+			// 99999999999 LAST" force a multi-gigabyte allocation.
+			// Compare against the remaining budget rather than adding size
+			// to len(sess.tx.data): size can be as large as MaxInt64, and
+			// that addition would overflow and wrap negative, letting an
+			// oversize chunk slip past the check straight into make().
+			if size > s.maxMessageSize-int64(len(sess.tx.data)) {
+				if _, err := io.CopyN(io.Discard, br, size); err != nil {
+					slog.Error("Failed to discard oversize BDAT chunk", "error", err.Error())
+					quit = true
+					continue
+				}
+
+				writeReplyAndFlush(bw, 552, "Message size exceeds fixed maximum message size")
+				sess.state = afterDATA
+				continue
+			}
+
+			chunk := make([]byte, size)
+			if _, err := io.ReadFull(br, chunk); err != nil {
+				slog.Error("Failed to read BDAT chunk", "error", err.Error())
+				quit = true
+				continue
+			}
+
+			sess.tx.data = append(sess.tx.data, chunk...)
+			sess.state = inDATA
+
+			if !last {
+				writeReplyAndFlush(bw, 250, fmt.Sprintf("%d octets received", size))
+				continue
+			}
+
+			if err := sess.backend.Data(bytes.NewReader(sess.tx.data)); err != nil {
+				slog.Error("Backend failed to accept message", "error", err.Error())
+				writeReplyAndFlush(bw, 554, "Transaction failed")
+				sess.state = afterDATA
+				continue
+			}
+
+			writeReplyAndFlush(bw, 250, fmt.Sprintf("Message OK, %d octets received", len(sess.tx.data)))
+			sess.state = afterDATA
+
 		case "QUIT":
 			quit = true
 		case "NOOP":
@@ -227,6 +773,9 @@ func (s *server) serveConn(conn net.Conn) {
 		case "RSET":
 			sess.state = beforeMAIL
 			sess.tx = &transaction{}
+			if sess.backend != nil {
+				sess.backend.Reset()
+			}
 			respOK(bw)
 
 		case "VRFY":
@@ -238,7 +787,9 @@ func (s *server) serveConn(conn net.Conn) {
 		}
 	}
 
-	writeReplyAndFlush(bw, 221, "Service closing transmission channel")
+	if !dropped {
+		writeReplyAndFlush(bw, 221, "Service closing transmission channel")
+	}
 }
 
 func respInvalidSyntax(bw *bufio.Writer) {
@@ -291,6 +842,30 @@ func readRCPTCommand(args string) string {
 	return ""
 }
 
+// parseBDATArgs parses the "<size> [LAST]" arguments of a BDAT command.
+func parseBDATArgs(args string) (int64, bool, error) {
+	fields := strings.Fields(args)
+	if len(fields) < 1 || len(fields) > 2 {
+		return 0, false, fmt.Errorf("malformed BDAT arguments")
+	}
+
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil || size < 0 {
+		return 0, false, fmt.Errorf("malformed BDAT size")
+	}
+
+	last := false
+	if len(fields) == 2 {
+		if !strings.EqualFold(fields[1], "LAST") {
+			return 0, false, fmt.Errorf("malformed BDAT arguments")
+		}
+
+		last = true
+	}
+
+	return size, last, nil
+}
+
 func readCommand(br *bufio.Reader) (string, string, error) {
 	l_, err := br.ReadString('\n')
 	if err != nil {
@@ -306,4 +881,4 @@ func readCommand(br *bufio.Reader) (string, string, error) {
 	}
 
 	return strings.ToUpper(cmd[0]), "", nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+)
+
+// proxyProtocolMode is the accepted values for --proxy-protocol.
+type proxyProtocolMode string
+
+const (
+	proxyProtocolOff      proxyProtocolMode = "off"
+	proxyProtocolV1       proxyProtocolMode = "v1"
+	proxyProtocolV2       proxyProtocolMode = "v2"
+	proxyProtocolOptional proxyProtocolMode = "optional"
+)
+
+// proxyV2Signature is the fixed 12-byte prefix of every PROXY protocol v2
+// header.
+var proxyV2Signature = []byte{0x0D, 0x0A, 0x0D, 0x0A, 0x00, 0x0D, 0x0A, 'Q', 'U', 'I', 'T', 0x0A}
+
+// PP2 TLV types we know how to label in logs (see the PROXY protocol spec).
+const (
+	pp2TypeAuthority = 0x02
+	pp2TypeSSL       = 0x20
+)
+
+// proxyHeader is the decoded result of a PROXY protocol header.
+type proxyHeader struct {
+	sourceAddr string
+	tlvs       map[byte][]byte
+}
+
+// logFields renders the decoded header as slog key/value pairs. serveConn
+// logs them once, when the header is decoded; they are not attached to the
+// connection's later log lines.
+func (h *proxyHeader) logFields() []any {
+	if h == nil {
+		return nil
+	}
+
+	fields := []any{"proxy_source_addr", h.sourceAddr}
+
+	if authority, ok := h.tlvs[pp2TypeAuthority]; ok {
+		fields = append(fields, "proxy_authority", string(authority))
+	}
+
+	if _, ok := h.tlvs[pp2TypeSSL]; ok {
+		fields = append(fields, "proxy_ssl", true)
+	}
+
+	return fields
+}
+
+// readProxyHeader consumes a PROXY protocol header from br according to
+// mode. In optional mode, if no header arrives within a short deadline, it
+// returns ok=false with a nil error so the caller falls back to conn's own
+// peer address.
+func readProxyHeader(conn net.Conn, br *bufio.Reader, mode proxyProtocolMode) (*proxyHeader, bool, error) {
+	if mode == proxyProtocolOptional {
+		conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		defer conn.SetReadDeadline(time.Time{})
+	}
+
+	peek, err := br.Peek(len(proxyV2Signature))
+	if err != nil {
+		if mode == proxyProtocolOptional {
+			return nil, false, nil
+		}
+
+		return nil, false, fmt.Errorf("proxyproto: peek header: %w", err)
+	}
+
+	if bytes.Equal(peek, proxyV2Signature) {
+		hdr, err := readProxyV2(br)
+		return hdr, err == nil, err
+	}
+
+	if mode == proxyProtocolV2 {
+		return nil, false, fmt.Errorf("proxyproto: expected a v2 header")
+	}
+
+	if bytes.HasPrefix(peek, []byte("PROXY ")) {
+		hdr, err := readProxyV1(br)
+		return hdr, err == nil, err
+	}
+
+	if mode == proxyProtocolOptional {
+		return nil, false, nil
+	}
+
+	return nil, false, fmt.Errorf("proxyproto: no PROXY header present")
+}
+
+// readProxyV1 parses the ASCII "PROXY TCP4/TCP6 src dst srcport dstport"
+// header, or "PROXY UNKNOWN".
+func readProxyV1(br *bufio.Reader) (*proxyHeader, error) {
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("proxyproto v1: read header: %w", err)
+	}
+
+	line = strings.TrimRight(line, "\r\n")
+
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[0] != "PROXY" {
+		return nil, fmt.Errorf("proxyproto v1: malformed header %q", line)
+	}
+
+	if fields[1] == "UNKNOWN" {
+		return &proxyHeader{}, nil
+	}
+
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("proxyproto v1: malformed header %q", line)
+	}
+
+	return &proxyHeader{sourceAddr: net.JoinHostPort(fields[2], fields[4])}, nil
+}
+
+// readProxyV2 parses the binary v2 header: the 12-byte signature (already
+// peeked by the caller), a 4-byte fixed header, and a variable-length
+// payload of addresses followed by TLVs.
+func readProxyV2(br *bufio.Reader) (*proxyHeader, error) {
+	fixed := make([]byte, 16)
+	if _, err := io.ReadFull(br, fixed); err != nil {
+		return nil, fmt.Errorf("proxyproto v2: read header: %w", err)
+	}
+
+	verCmd := fixed[12]
+	famProto := fixed[13]
+	length := binary.BigEndian.Uint16(fixed[14:16])
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(br, payload); err != nil {
+		return nil, fmt.Errorf("proxyproto v2: read payload: %w", err)
+	}
+
+	// LOCAL connections (e.g. health checks) carry no address to decode.
+	if verCmd&0x0F == 0x00 {
+		return &proxyHeader{}, nil
+	}
+
+	var sourceAddr string
+	var addrLen int
+
+	switch famProto >> 4 {
+	case 0x1: // AF_INET
+		addrLen = 12
+		if len(payload) >= addrLen {
+			srcIP := net.IP(payload[0:4])
+			srcPort := binary.BigEndian.Uint16(payload[8:10])
+			sourceAddr = net.JoinHostPort(srcIP.String(), fmt.Sprintf("%d", srcPort))
+		}
+	case 0x2: // AF_INET6
+		addrLen = 36
+		if len(payload) >= addrLen {
+			srcIP := net.IP(payload[0:16])
+			srcPort := binary.BigEndian.Uint16(payload[32:34])
+			sourceAddr = net.JoinHostPort(srcIP.String(), fmt.Sprintf("%d", srcPort))
+		}
+	}
+
+	var tlvs map[byte][]byte
+	if addrLen <= len(payload) {
+		tlvs = parseProxyTLVs(payload[addrLen:])
+	}
+
+	return &proxyHeader{sourceAddr: sourceAddr, tlvs: tlvs}, nil
+}
+
+// parseProxyTLVs walks the type-length-value block following the fixed
+// address fields in a v2 header.
+func parseProxyTLVs(b []byte) map[byte][]byte {
+	tlvs := map[byte][]byte{}
+
+	for len(b) >= 3 {
+		t := b[0]
+		l := int(binary.BigEndian.Uint16(b[1:3]))
+
+		if len(b) < 3+l {
+			slog.Warn("Truncated PROXY protocol TLV", "type", t)
+			break
+		}
+
+		tlvs[t] = b[3 : 3+l]
+		b = b[3+l:]
+	}
+
+	return tlvs
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// tlsClientAuthMode is the accepted values for --tls-client-auth.
+type tlsClientAuthMode string
+
+const (
+	tlsClientAuthNone    tlsClientAuthMode = "none"
+	tlsClientAuthRequest tlsClientAuthMode = "request"
+	tlsClientAuthRequire tlsClientAuthMode = "require"
+)
+
+// loadTLSConfig builds the *tls.Config used for both STARTTLS and implicit
+// TLS from the configured cert/key pair and client-auth mode. It returns
+// (nil, nil) when no cert/key were configured, meaning TLS is disabled.
+func loadTLSConfig(certFile, keyFile string, clientAuth tlsClientAuthMode) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("tls: both --tls-cert and --tls-key must be set")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("tls: load key pair: %w", err)
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+
+	switch clientAuth {
+	case tlsClientAuthNone, "":
+		cfg.ClientAuth = tls.NoClientCert
+	case tlsClientAuthRequest:
+		cfg.ClientAuth = tls.RequestClientCert
+	case tlsClientAuthRequire:
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("tls: unknown --tls-client-auth value %q", clientAuth)
+	}
+
+	return cfg, nil
+}